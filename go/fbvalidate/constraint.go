@@ -0,0 +1,80 @@
+package fbvalidate
+
+import "fmt"
+
+// FieldConstraint is one constraint attached to a FlatBuffer field via a
+// `.fbs` attribute, e.g. `value_range:"0,100"` or `required`. Check is
+// called with the field's decoded Go value (bool/int64/uint64/float64/
+// string, as read off the wire) and whether the field was present on the
+// wire at all; it returns a human-readable failure reason, or "" if the
+// value satisfies the constraint.
+type FieldConstraint struct {
+	Name  string
+	Check func(value interface{}, present bool) string
+}
+
+// Required rejects a field that the sender didn't set at all.
+func Required() FieldConstraint {
+	return FieldConstraint{
+		Name: "required",
+		Check: func(_ interface{}, present bool) string {
+			if !present {
+				return "required field not set"
+			}
+			return ""
+		},
+	}
+}
+
+// ValueRange rejects a present numeric field outside [min, max].
+func ValueRange(min, max float64) FieldConstraint {
+	return FieldConstraint{
+		Name: fmt.Sprintf("value_range:%g,%g", min, max),
+		Check: func(value interface{}, present bool) string {
+			if !present {
+				return ""
+			}
+			f, ok := asFloat64(value)
+			if !ok {
+				return fmt.Sprintf("value_range requires a numeric field, got %T", value)
+			}
+			if f < min || f > max {
+				return fmt.Sprintf("value %v outside range [%g, %g]", value, min, max)
+			}
+			return ""
+		},
+	}
+}
+
+// LenMax rejects a present string field longer than max bytes.
+func LenMax(max int) FieldConstraint {
+	return FieldConstraint{
+		Name: fmt.Sprintf("len_max:%d", max),
+		Check: func(value interface{}, present bool) string {
+			if !present {
+				return ""
+			}
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Sprintf("len_max requires a string field, got %T", value)
+			}
+			if len(s) > max {
+				return fmt.Sprintf("length %d exceeds len_max %d", len(s), max)
+			}
+			return ""
+		},
+	}
+}
+
+func asFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
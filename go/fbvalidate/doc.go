@@ -0,0 +1,20 @@
+// Package fbvalidate enforces payload constraints on AOS gRPC messages at
+// the edge, analogous to what buf.build/gen/go/bufbuild/protovalidate does
+// for protobuf messages.
+//
+// Schema authors annotate FlatBuffer fields in their `.fbs` files with
+// attributes like `required`, `value_range:"0,100"`, and `len_max:"64"`
+// (see FieldConstraint); a generator would read those annotations off a
+// channel's reflection schema (`.bfbs`) and emit a TableValidator per
+// table, the same way go/grpcgateway's SchemaRegistry is populated from
+// per-channel schemas. Tests in this package build TableValidators by
+// hand for representative AOS schemas in the absence of that generator.
+//
+// UnaryServerInterceptor and UnaryClientInterceptor apply a Registry's
+// validators to messages, keyed by the channel named in the call's
+// "aos-channel" gRPC metadata (see go/grpcgateway's channelMetadataKey
+// convention), and reject ones that fail with a structured
+// codes.InvalidArgument error listing every failing constraint. A
+// ChannelHzMax constraint is enforced separately via a small ring buffer
+// per (client, channel), capping publish rate without custom middleware.
+package fbvalidate
@@ -0,0 +1,230 @@
+package fbvalidate
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Representative AOS channel schemas, each with the constrained fields a
+// generator would have emitted from their `.fbs` attributes.
+
+var imuValidator = &TableValidator{
+	Name: "Imu",
+	Fields: []Field{
+		{Name: "accel_x", Type: FieldFloat32, Offset: 4, Constraints: []FieldConstraint{Required(), ValueRange(-16, 16)}},
+		{Name: "accel_y", Type: FieldFloat32, Offset: 6, Constraints: []FieldConstraint{Required(), ValueRange(-16, 16)}},
+	},
+	ChannelHzMax: 200,
+}
+
+var joystickValidator = &TableValidator{
+	Name: "Joystick",
+	Fields: []Field{
+		{Name: "buttons", Type: FieldUint32, Offset: 4, Constraints: []FieldConstraint{ValueRange(0, 1023)}},
+	},
+	ChannelHzMax: 50,
+}
+
+var logMessageValidator = &TableValidator{
+	Name: "LogMessage",
+	Fields: []Field{
+		{Name: "message", Type: FieldString, Offset: 4, Constraints: []FieldConstraint{Required(), LenMax(4096)}},
+	},
+}
+
+// buildFloatTable serializes a FlatBuffer table with the given scalar fields
+// set, in field-index order, mirroring the vtable offsets used above
+// (field i gets offset (i+2)*2, i.e. 4, 6, 8, ...). Fields are built at
+// the same narrow widths real flatc-generated accel/buttons fields use,
+// not the 64-bit accessors.
+func buildFloatTable(t *testing.T, values ...float64) []byte {
+	t.Helper()
+	b := flatbuffers.NewBuilder(0)
+	b.StartObject(len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		b.PrependFloat32Slot(i, float32(values[i]), 0)
+	}
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+func buildUintTable(t *testing.T, value uint64) []byte {
+	t.Helper()
+	b := flatbuffers.NewBuilder(0)
+	b.StartObject(1)
+	b.PrependUint32Slot(0, uint32(value), 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+func buildStringTable(t *testing.T, s string) []byte {
+	t.Helper()
+	b := flatbuffers.NewBuilder(0)
+	str := b.CreateString(s)
+	b.StartObject(1)
+	b.PrependUOffsetTSlot(0, str, 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+func TestImuValidatorRejectsOutOfRangeAccel(t *testing.T) {
+	if failures := imuValidator.Validate(buildFloatTable(t, 1, 2)); failures != nil {
+		t.Errorf("in-range accel: Validate() = %v, want nil", failures)
+	}
+	failures := imuValidator.Validate(buildFloatTable(t, 20, 2))
+	if len(failures) != 1 {
+		t.Fatalf("out-of-range accel_x: Validate() = %v, want exactly one failure", failures)
+	}
+}
+
+func TestImuValidatorRequiresFields(t *testing.T) {
+	failures := imuValidator.Validate(nil)
+	if len(failures) != 2 {
+		t.Fatalf("empty message: Validate() = %v, want 2 required-field failures", failures)
+	}
+}
+
+func TestJoystickValidatorRejectsOutOfRangeButtons(t *testing.T) {
+	if failures := joystickValidator.Validate(buildUintTable(t, 7)); failures != nil {
+		t.Errorf("in-range buttons: Validate() = %v, want nil", failures)
+	}
+	if failures := joystickValidator.Validate(buildUintTable(t, 5000)); len(failures) != 1 {
+		t.Errorf("out-of-range buttons: Validate() = %v, want exactly one failure", failures)
+	}
+}
+
+func TestLogMessageValidatorEnforcesLenMax(t *testing.T) {
+	if failures := logMessageValidator.Validate(buildStringTable(t, "booted")); failures != nil {
+		t.Errorf("short message: Validate() = %v, want nil", failures)
+	}
+	long := make([]byte, 5000)
+	if failures := logMessageValidator.Validate(buildStringTable(t, string(long))); len(failures) != 1 {
+		t.Errorf("long message: Validate() = %v, want exactly one len_max failure", failures)
+	}
+	if failures := logMessageValidator.Validate(nil); len(failures) != 1 {
+		t.Errorf("missing message: Validate() = %v, want exactly one required failure", failures)
+	}
+}
+
+// TestJoystickValidatorReadsNarrowWidthCorrectly guards against reading a
+// uint32 field with the 64-bit accessor, which would read 4 bytes of
+// trailing vtable/padding past the field instead of its actual value and
+// either panic or validate against garbage.
+func TestJoystickValidatorReadsNarrowWidthCorrectly(t *testing.T) {
+	if failures := joystickValidator.Validate(buildUintTable(t, 7)); failures != nil {
+		t.Errorf("Validate() = %v, want nil for in-range buttons:uint32 value 7", failures)
+	}
+}
+
+func TestValidateReportsMalformedTableInsteadOfPanicking(t *testing.T) {
+	for _, raw := range [][]byte{{0x01}, {0xff, 0xff, 0xff, 0xff}} {
+		failures := logMessageValidator.Validate(raw)
+		if len(failures) != 1 {
+			t.Errorf("Validate(%v) = %v, want exactly one malformed-table failure", raw, failures)
+		}
+	}
+}
+
+func TestRateLimiterEnforcesHz(t *testing.T) {
+	limiter := newRateLimiter()
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 3; i++ {
+		if !limiter.allow("node|/imu", 3, now) {
+			t.Fatalf("send %d within limit was rejected", i)
+		}
+		now = now.Add(10 * time.Millisecond)
+	}
+	if limiter.allow("node|/imu", 3, now) {
+		t.Error("4th send within a second should have been rejected")
+	}
+	if !limiter.allow("node|/imu", 3, now.Add(2*time.Second)) {
+		t.Error("send two seconds later should be allowed again")
+	}
+}
+
+// rawBytesCodec passes []byte payloads through unmodified, the wire
+// convention AOS channel messages use (see go/grpcgateway's bytesCodec).
+type rawBytesCodec struct{}
+
+func (rawBytesCodec) Marshal(v interface{}) ([]byte, error) {
+	return *v.(*[]byte), nil
+}
+
+func (rawBytesCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawBytesCodec) Name() string { return "aos-flatbuffer" }
+
+const sendMethod = "/aos.grpc.ChannelService/Send"
+
+var sendServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aos.grpc.ChannelService",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Send",
+		Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new([]byte)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				var reply []byte
+				return &reply, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: sendMethod}, handler)
+		},
+	}},
+}
+
+func TestUnaryServerInterceptorRejectsInvalidMessage(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("/joystick", joystickValidator)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(reg)),
+		grpc.ForceServerCodec(rawBytesCodec{}),
+	)
+	s.RegisterService(&sendServiceDesc, nil)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	// nolint:staticcheck // matches the grpc version this module is pinned to.
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	send := func(buttons uint64) error {
+		req := buildUintTable(t, buttons)
+		var reply []byte
+		ctx := metadata.AppendToOutgoingContext(context.Background(), channelMetadataKey, "/joystick")
+		return conn.Invoke(ctx, sendMethod, &req, &reply, grpc.ForceCodec(rawBytesCodec{}))
+	}
+
+	if err := send(7); err != nil {
+		t.Errorf("valid message rejected: %v", err)
+	}
+	err = send(5000)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid message: err = %v, want codes.InvalidArgument", err)
+	}
+}
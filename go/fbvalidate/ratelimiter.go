@@ -0,0 +1,63 @@
+package fbvalidate
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter caps publish rate per (client, channel) using a small ring
+// buffer of recent send timestamps per key, so operators get
+// ChannelHzMax enforcement without writing custom middleware.
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// window is a fixed-size ring buffer holding the timestamps of the last
+// len(times) sends for one key, where len(times) is the allowed rate
+// rounded down to a whole number of sends (at least 1). Once count reaches
+// len(times), each new send evicts the oldest timestamp in the buffer; if
+// that timestamp is still less than len(times)/maxHz seconds old (one
+// second for a whole-number rate), the buffer already held the allowed
+// number of sends for that span and the new one is over the limit.
+type window struct {
+	times []time.Time
+	pos   int
+	count int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: make(map[string]*window)}
+}
+
+// allow records a send for key at now and reports whether it satisfies a
+// limit of maxHz messages/sec. maxHz <= 0 means unlimited.
+func (r *rateLimiter) allow(key string, maxHz float64, now time.Time) bool {
+	if maxHz <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	size := int(maxHz)
+	if size < 1 {
+		size = 1
+	}
+	w, ok := r.windows[key]
+	if !ok || len(w.times) != size {
+		w = &window{times: make([]time.Time, size)}
+		r.windows[key] = w
+	}
+
+	full := w.count >= size
+	oldest := w.times[w.pos]
+	w.times[w.pos] = now
+	w.pos = (w.pos + 1) % size
+	w.count++
+
+	if !full {
+		return true
+	}
+	return now.Sub(oldest) >= time.Duration(float64(size)/maxHz*float64(time.Second))
+}
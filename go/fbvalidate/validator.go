@@ -0,0 +1,152 @@
+package fbvalidate
+
+import (
+	"fmt"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/RealtimeRoboticsGroup/aos/go/internal/fbwire"
+)
+
+// FieldType mirrors the FlatBuffer scalar kinds fbvalidate knows how to
+// read off the wire for constraint checking. Nested tables and vectors
+// aren't validated directly; annotate the scalar fields inside them
+// instead. Integer and float kinds are split by width (e.g. FieldUint32
+// vs FieldUint64) because flatc always generates the narrowest accessor
+// that fits a field's declared type, and reading a narrower field with a
+// wider accessor reads past it into whatever follows on the wire.
+type FieldType int
+
+const (
+	FieldBool FieldType = iota
+	FieldInt8
+	FieldInt16
+	FieldInt32
+	FieldInt64
+	FieldUint8
+	FieldUint16
+	FieldUint32
+	FieldUint64
+	FieldFloat32
+	FieldFloat64
+	FieldString
+)
+
+// width reports the wire width backing a scalar FieldType, for dispatching
+// to the matching fbwire accessor. FieldBool and FieldString have exactly
+// one width each and don't need it.
+func (t FieldType) width() fbwire.Width {
+	switch t {
+	case FieldInt8, FieldUint8:
+		return fbwire.Width8
+	case FieldInt16, FieldUint16:
+		return fbwire.Width16
+	case FieldInt32, FieldUint32, FieldFloat32:
+		return fbwire.Width32
+	default:
+		return fbwire.Width64
+	}
+}
+
+// Field describes one constrained field of a FlatBuffer table: its vtable
+// offset (see flatbuffers.Table.Offset), its scalar type, and the
+// constraints a generator attached to it from `.fbs` field attributes.
+type Field struct {
+	Name        string
+	Type        FieldType
+	Offset      uint16
+	Constraints []FieldConstraint
+}
+
+// TableValidator validates one FlatBuffer table type's wire-level
+// constraints: one Field per constrained field, plus an optional
+// ChannelHzMax rate limit, applied to each sender on that table's channel
+// separately.
+type TableValidator struct {
+	Name         string
+	Fields       []Field
+	ChannelHzMax float64 // 0 means unlimited
+}
+
+// Validate decodes raw according to v's fields and returns every
+// constraint violation found, or nil if raw satisfies all of them. An
+// empty raw is treated as a table with no fields present, so Required
+// constraints still fire. A raw that isn't a well-formed table for v's
+// schema (truncated, or with offsets pointing outside the buffer) is
+// reported as a single "malformed table" failure rather than panicking,
+// since raw may be attacker-controlled by the time it reaches an edge
+// validator.
+func (v *TableValidator) Validate(raw []byte) (failures []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			failures = []string{fmt.Sprintf("%s: malformed table: %v", v.Name, r)}
+		}
+	}()
+
+	var t *flatbuffers.Table
+	if len(raw) > 0 {
+		t = &flatbuffers.Table{Bytes: raw, Pos: flatbuffers.GetUOffsetT(raw)}
+	}
+
+	for _, f := range v.Fields {
+		value, present := readField(t, f)
+		for _, c := range f.Constraints {
+			if msg := c.Check(value, present); msg != "" {
+				failures = append(failures, fmt.Sprintf("%s: %s (%s)", f.Name, msg, c.Name))
+			}
+		}
+	}
+	return failures
+}
+
+func readField(t *flatbuffers.Table, f Field) (value interface{}, present bool) {
+	if t == nil {
+		return nil, false
+	}
+	off := t.Offset(flatbuffers.VOffsetT(f.Offset))
+	if off == 0 {
+		return nil, false
+	}
+	pos := t.Pos + flatbuffers.UOffsetT(off)
+
+	switch f.Type {
+	case FieldBool:
+		return t.Bytes[pos] != 0, true
+	case FieldInt8, FieldInt16, FieldInt32, FieldInt64:
+		return fbwire.ReadInt(t.Bytes, pos, f.Type.width()), true
+	case FieldUint8, FieldUint16, FieldUint32, FieldUint64:
+		return fbwire.ReadUint(t.Bytes, pos, f.Type.width()), true
+	case FieldFloat32, FieldFloat64:
+		return fbwire.ReadFloat(t.Bytes, pos, f.Type.width()), true
+	case FieldString:
+		return string(t.ByteVector(pos)), true
+	default:
+		return nil, true
+	}
+}
+
+// Registry maps AOS channel names to the TableValidator for the message
+// type sent on that channel, the same way grpcgateway.SchemaRegistry maps
+// channels to JSON marshaling schemas. A generator would populate one per
+// AOS build from each channel's reflection schema; tests build them by
+// hand.
+type Registry struct {
+	byChannel map[string]*TableValidator
+}
+
+// NewRegistry returns an empty registry. Use Register to populate it.
+func NewRegistry() *Registry {
+	return &Registry{byChannel: make(map[string]*TableValidator)}
+}
+
+// Register associates validator with channel, overwriting any previous
+// registration for that channel name.
+func (r *Registry) Register(channel string, validator *TableValidator) {
+	r.byChannel[channel] = validator
+}
+
+// Lookup returns the validator registered for channel, if any.
+func (r *Registry) Lookup(channel string) (*TableValidator, bool) {
+	v, ok := r.byChannel[channel]
+	return v, ok
+}
@@ -0,0 +1,94 @@
+package grpcgateway
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ChannelBackend is the minimal surface Server needs from an AOS gRPC
+// channel connection: fetch the latest message on a channel, send one, and
+// subscribe to a live stream of messages. NewServer builds the default
+// implementation over a *grpc.ClientConn; NewServerWithBackend lets callers
+// substitute another source (tests use a fake).
+type ChannelBackend interface {
+	// Fetch returns the latest FlatBuffer message on channel, or an error
+	// if none has been sent yet.
+	Fetch(ctx context.Context, channel string) ([]byte, error)
+	// Send publishes payload on channel.
+	Send(ctx context.Context, channel string, payload []byte) error
+	// Stream returns a channel of successive FlatBuffer messages published
+	// on channel. The returned channel is closed when ctx is done or the
+	// underlying stream ends.
+	Stream(ctx context.Context, channel string) (<-chan []byte, error)
+}
+
+// channelMetadataKey is the gRPC metadata key grpcChannelBackend uses to
+// tell AOS's channel service which channel a Fetch/Send/Stream call is
+// for; the RPC itself takes no other request fields.
+const channelMetadataKey = "aos-channel"
+
+// RPC names for AOS's channel gRPC service. The method set is the same
+// for every channel -- only channelMetadataKey differs -- so
+// grpcChannelBackend talks to them directly via ClientConn.Invoke/NewStream
+// rather than through generated per-channel stubs.
+const (
+	methodFetch  = "/aos.grpc.ChannelService/Fetch"
+	methodSend   = "/aos.grpc.ChannelService/Send"
+	methodStream = "/aos.grpc.ChannelService/Stream"
+)
+
+// grpcChannelBackend is the default ChannelBackend, talking to AOS's
+// channel service over conn using raw FlatBuffer bytes (bytesCodec)
+// instead of a protobuf codec.
+type grpcChannelBackend struct {
+	conn *grpc.ClientConn
+}
+
+func (b *grpcChannelBackend) Fetch(ctx context.Context, channel string) ([]byte, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, channelMetadataKey, channel)
+	req := []byte{}
+	var reply []byte
+	if err := b.conn.Invoke(ctx, methodFetch, &req, &reply, grpc.ForceCodec(bytesCodec{})); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (b *grpcChannelBackend) Send(ctx context.Context, channel string, payload []byte) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, channelMetadataKey, channel)
+	var reply []byte
+	return b.conn.Invoke(ctx, methodSend, &payload, &reply, grpc.ForceCodec(bytesCodec{}))
+}
+
+func (b *grpcChannelBackend) Stream(ctx context.Context, channel string) (<-chan []byte, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, channelMetadataKey, channel)
+	cs, err := b.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, methodStream, grpc.ForceCodec(bytesCodec{}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.SendMsg([]byte{}); err != nil {
+		return nil, err
+	}
+	if err := cs.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		for {
+			var msg []byte
+			if err := cs.RecvMsg(&msg); err != nil {
+				return
+			}
+			select {
+			case out <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package grpcgateway
+
+import "fmt"
+
+// bytesCodec is a grpc/encoding.Codec that passes FlatBuffer payloads over
+// the wire unmodified. AOS channel messages are already serialized bytes
+// by the time they reach gRPC -- there is no protobuf type to marshal
+// through -- so Marshal/Unmarshal are just a copy in and out of a []byte.
+//
+// It is used as a grpc.ForceCodec call option on the client side
+// (grpcChannelBackend) and as the server codec in tests, so both ends
+// agree to skip protobuf entirely for the channel service's raw methods.
+type bytesCodec struct{}
+
+// Marshal implements encoding.Codec.
+func (bytesCodec) Marshal(v interface{}) ([]byte, error) {
+	switch p := v.(type) {
+	case *[]byte:
+		return *p, nil
+	case []byte:
+		return p, nil
+	default:
+		return nil, fmt.Errorf("grpcgateway: bytesCodec.Marshal expects []byte, got %T", v)
+	}
+}
+
+// Unmarshal implements encoding.Codec.
+func (bytesCodec) Unmarshal(data []byte, v interface{}) error {
+	p, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("grpcgateway: bytesCodec.Unmarshal expects *[]byte, got %T", v)
+	}
+	*p = append([]byte(nil), data...)
+	return nil
+}
+
+// Name implements encoding.Codec.
+func (bytesCodec) Name() string { return "aos-flatbuffer" }
@@ -0,0 +1,29 @@
+// Package grpcgateway exposes AOS channels -- normally reachable only via raw
+// gRPC and FlatBuffers -- as a REST/SSE HTTP surface, the way
+// grpc-ecosystem/grpc-gateway does for protobuf services. Routing and
+// channel-to-schema lookup here are hand-rolled rather than generated: a
+// Server mounts a fixed net/http mux and consults a SchemaRegistry that
+// callers populate explicitly via SchemaRegistry.Register, one call per
+// channel. This package reuses grpc-gateway/v2/runtime only for its
+// Marshaler interface shape (see FlatbufferMarshaler); it does not use
+// grpc-gateway's reflection-based service discovery or code generation, and
+// there is currently no equivalent for FlatBuffer channels -- building a
+// schema manifest (or deriving TableSchemas from a channel's .bfbs
+// reflection schema, see TableSchema) so channels don't need to be
+// registered by hand is unimplemented follow-up scope.
+//
+// A Server wraps a ClientConn pointed at an AOS node's gRPC channel service
+// and mounts three endpoints per registered channel, where {channel} is the
+// AOS channel name including its own leading slash (e.g. "/channels/joystick"
+// for channel "/joystick", "/channels/drivetrain/status" for channel
+// "/drivetrain/status"):
+//
+//	GET  /channels{channel}         latest value as JSON
+//	GET  /channels{channel}/stream  Server-Sent-Events stream of fetcher output
+//	POST /channels{channel}         send a JSON-encoded message
+//
+// Because channel payloads are FlatBuffers rather than protobuf messages,
+// translation to/from JSON goes through a TableSchema describing the
+// FlatBuffer table layout (see FlatbufferMarshaler), rather than through
+// protobuf's generated descriptors.
+package grpcgateway
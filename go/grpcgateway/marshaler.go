@@ -0,0 +1,410 @@
+package grpcgateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"github.com/RealtimeRoboticsGroup/aos/go/internal/fbwire"
+)
+
+// FlatbufferMarshaler implements runtime.Marshaler (grpc-gateway's
+// conversion interface) for a single FlatBuffer table type, translating
+// between wire bytes and JSON using a TableSchema instead of protobuf
+// descriptors. Server constructs one per request from the schema registered
+// for the channel via SchemaRegistry.Register; callers normally don't need
+// to use this type directly.
+type FlatbufferMarshaler struct {
+	schema *TableSchema
+}
+
+// NewFlatbufferMarshaler returns a marshaler that reads and writes tables
+// shaped like schema.
+func NewFlatbufferMarshaler(schema *TableSchema) *FlatbufferMarshaler {
+	return &FlatbufferMarshaler{schema: schema}
+}
+
+// ContentType implements runtime.Marshaler.
+func (m *FlatbufferMarshaler) ContentType(interface{}) string {
+	return "application/json"
+}
+
+// Marshal implements runtime.Marshaler, converting v into JSON. v must be
+// []byte containing a serialized FlatBuffer table matching m.schema.
+func (m *FlatbufferMarshaler) Marshal(v interface{}) ([]byte, error) {
+	raw, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("grpcgateway: Marshal expects []byte flatbuffer payload, got %T", v)
+	}
+	obj, err := tableToMap(raw, m.schema)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// Unmarshal implements runtime.Marshaler, converting a JSON document back
+// into a serialized FlatBuffer table matching m.schema. v must be *[]byte.
+func (m *FlatbufferMarshaler) Unmarshal(data []byte, v interface{}) error {
+	out, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("grpcgateway: Unmarshal expects *[]byte destination, got %T", v)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	b := flatbuffers.NewBuilder(0)
+	offset, err := mapToTable(b, obj, m.schema)
+	if err != nil {
+		return err
+	}
+	b.Finish(offset)
+	*out = b.FinishedBytes()
+	return nil
+}
+
+// NewDecoder implements runtime.Marshaler.
+func (m *FlatbufferMarshaler) NewDecoder(r io.Reader) runtime.Decoder {
+	return runtime.DecoderFunc(func(v interface{}) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return m.Unmarshal(data, v)
+	})
+}
+
+// NewEncoder implements runtime.Marshaler.
+func (m *FlatbufferMarshaler) NewEncoder(w io.Writer) runtime.Encoder {
+	return runtime.EncoderFunc(func(v interface{}) error {
+		data, err := m.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+}
+
+// tableToMap reads a serialized FlatBuffer table according to schema and
+// returns its fields as a JSON-friendly map. raw that isn't a well-formed
+// table for schema (truncated, or with offsets pointing outside the
+// buffer) is reported as an error rather than panicking, since raw comes
+// straight off the wire from an AOS node.
+func tableToMap(raw []byte, schema *TableSchema) (out map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			out, err = nil, fmt.Errorf("grpcgateway: malformed table %q: %v", schema.Name, r)
+		}
+	}()
+
+	if len(raw) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	t := &flatbuffers.Table{}
+	t.Bytes = raw
+	t.Pos = flatbuffers.GetUOffsetT(raw)
+	return nestedTableToMap(t, schema)
+}
+
+func readField(t *flatbuffers.Table, off flatbuffers.UOffsetT, f FieldSchema) (interface{}, error) {
+	switch f.Type {
+	case FieldBool:
+		return t.Bytes[t.Pos+off] != 0, nil
+	case FieldInt8, FieldInt16, FieldInt32, FieldInt64:
+		return fbwire.ReadInt(t.Bytes, t.Pos+off, f.Type.width()), nil
+	case FieldUint8, FieldUint16, FieldUint32, FieldUint64:
+		return fbwire.ReadUint(t.Bytes, t.Pos+off, f.Type.width()), nil
+	case FieldFloat32, FieldFloat64:
+		return fbwire.ReadFloat(t.Bytes, t.Pos+off, f.Type.width()), nil
+	case FieldString:
+		return string(t.ByteVector(t.Pos + off)), nil
+	case FieldTable:
+		if f.Elem == nil {
+			return nil, fmt.Errorf("table field missing nested schema")
+		}
+		pos := t.Pos + off
+		pos += flatbuffers.GetUOffsetT(t.Bytes[pos:])
+		nested := &flatbuffers.Table{Bytes: t.Bytes, Pos: pos}
+		return nestedTableToMap(nested, f.Elem)
+	case FieldVector:
+		return readVector(t, off, f)
+	default:
+		return nil, fmt.Errorf("unsupported field type %v", f.Type)
+	}
+}
+
+func nestedTableToMap(t *flatbuffers.Table, schema *TableSchema) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(schema.Fields))
+	for _, f := range schema.Fields {
+		off := t.Offset(flatbuffers.VOffsetT(f.Offset))
+		if off == 0 {
+			continue
+		}
+		val, err := readField(t, flatbuffers.UOffsetT(off), f)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.Name, err)
+		}
+		out[f.Name] = val
+	}
+	return out, nil
+}
+
+// readVector reads the vector field at off (t's raw, not-yet-dereferenced
+// vtable offset, exactly as Table.Vector/VectorLen expect) into a
+// JSON-friendly slice, dispatching on f.ElemType the same way readField
+// dispatches on f.Type.
+func readVector(t *flatbuffers.Table, off flatbuffers.UOffsetT, f FieldSchema) (interface{}, error) {
+	n := t.VectorLen(off)
+	start := t.Vector(off)
+
+	switch f.ElemType {
+	case FieldBool:
+		out := make([]bool, n)
+		for i := 0; i < n; i++ {
+			out[i] = t.Bytes[start+flatbuffers.UOffsetT(i)] != 0
+		}
+		return out, nil
+	case FieldInt8, FieldInt16, FieldInt32, FieldInt64:
+		w := f.ElemType.width()
+		out := make([]int64, n)
+		for i := 0; i < n; i++ {
+			out[i] = fbwire.ReadInt(t.Bytes, start+flatbuffers.UOffsetT(i*w.Size()), w)
+		}
+		return out, nil
+	case FieldUint8, FieldUint16, FieldUint32, FieldUint64:
+		w := f.ElemType.width()
+		out := make([]uint64, n)
+		for i := 0; i < n; i++ {
+			out[i] = fbwire.ReadUint(t.Bytes, start+flatbuffers.UOffsetT(i*w.Size()), w)
+		}
+		return out, nil
+	case FieldFloat32, FieldFloat64:
+		w := f.ElemType.width()
+		out := make([]float64, n)
+		for i := 0; i < n; i++ {
+			out[i] = fbwire.ReadFloat(t.Bytes, start+flatbuffers.UOffsetT(i*w.Size()), w)
+		}
+		return out, nil
+	case FieldString:
+		out := make([]string, n)
+		for i := 0; i < n; i++ {
+			elemOff := start + flatbuffers.UOffsetT(i*flatbuffers.SizeUOffsetT)
+			out[i] = string(t.ByteVector(elemOff))
+		}
+		return out, nil
+	case FieldTable:
+		if f.Elem == nil {
+			return nil, fmt.Errorf("vector-of-table field missing nested schema")
+		}
+		out := make([]map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			elemOff := start + flatbuffers.UOffsetT(i*flatbuffers.SizeUOffsetT)
+			pos := elemOff + flatbuffers.GetUOffsetT(t.Bytes[elemOff:])
+			nested := &flatbuffers.Table{Bytes: t.Bytes, Pos: pos}
+			m, err := nestedTableToMap(nested, f.Elem)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			out[i] = m
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported vector element type %v", f.ElemType)
+	}
+}
+
+// mapToTable serializes obj into b according to schema, returning the
+// offset of the finished table. A panic while encoding (e.g. a width
+// mismatch working out the vtable layout) is reported as an error rather
+// than crashing the caller, mirroring tableToMap.
+func mapToTable(b *flatbuffers.Builder, obj map[string]interface{}, schema *TableSchema) (offset flatbuffers.UOffsetT, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			offset, err = 0, fmt.Errorf("grpcgateway: panic encoding table %q: %v", schema.Name, r)
+		}
+	}()
+
+	// Strings, nested tables, and vectors must be built before StartObject,
+	// per FlatBuffers' builder rules; build them first and stash their
+	// offsets.
+	refOffsets := make(map[string]flatbuffers.UOffsetT)
+	for _, f := range schema.Fields {
+		v, ok := obj[f.Name]
+		if !ok {
+			continue
+		}
+		switch f.Type {
+		case FieldString:
+			s, ok := v.(string)
+			if !ok {
+				return 0, fmt.Errorf("field %q: expected string, got %T", f.Name, v)
+			}
+			refOffsets[f.Name] = b.CreateString(s)
+		case FieldTable:
+			if f.Elem == nil {
+				return 0, fmt.Errorf("field %q: missing nested schema", f.Name)
+			}
+			nested, ok := v.(map[string]interface{})
+			if !ok {
+				return 0, fmt.Errorf("field %q: expected object, got %T", f.Name, v)
+			}
+			off, err := mapToTable(b, nested, f.Elem)
+			if err != nil {
+				return 0, fmt.Errorf("field %q: %w", f.Name, err)
+			}
+			refOffsets[f.Name] = off
+		case FieldVector:
+			off, err := vectorToOffset(b, v, f)
+			if err != nil {
+				return 0, fmt.Errorf("field %q: %w", f.Name, err)
+			}
+			refOffsets[f.Name] = off
+		}
+	}
+
+	b.StartObject(len(schema.Fields))
+	for i, f := range schema.Fields {
+		v, ok := obj[f.Name]
+		if !ok {
+			continue
+		}
+		switch f.Type {
+		case FieldBool:
+			bv, ok := v.(bool)
+			if !ok {
+				return 0, fmt.Errorf("field %q: expected bool, got %T", f.Name, v)
+			}
+			b.PrependBoolSlot(i, bv, false)
+		case FieldInt8, FieldInt16, FieldInt32, FieldInt64:
+			n, err := jsonNumberToInt64(v)
+			if err != nil {
+				return 0, fmt.Errorf("field %q: %w", f.Name, err)
+			}
+			fbwire.PrependInt(b, i, n, f.Type.width())
+		case FieldUint8, FieldUint16, FieldUint32, FieldUint64:
+			n, err := jsonNumberToInt64(v)
+			if err != nil {
+				return 0, fmt.Errorf("field %q: %w", f.Name, err)
+			}
+			fbwire.PrependUint(b, i, uint64(n), f.Type.width())
+		case FieldFloat32, FieldFloat64:
+			f64, ok := v.(float64)
+			if !ok {
+				return 0, fmt.Errorf("field %q: expected number, got %T", f.Name, v)
+			}
+			fbwire.PrependFloat(b, i, f64, f.Type.width())
+		case FieldString, FieldTable, FieldVector:
+			b.PrependUOffsetTSlot(i, refOffsets[f.Name], 0)
+		default:
+			return 0, fmt.Errorf("field %q: unsupported field type %v for encoding", f.Name, f.Type)
+		}
+	}
+	return b.EndObject(), nil
+}
+
+// vectorToOffset builds a FlatBuffers vector from the JSON array v
+// according to f.ElemType, returning its offset. Must be called before the
+// enclosing table's StartObject, per FlatBuffers' builder rules.
+func vectorToOffset(b *flatbuffers.Builder, v interface{}, f FieldSchema) (flatbuffers.UOffsetT, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("expected array, got %T", v)
+	}
+
+	switch f.ElemType {
+	case FieldBool:
+		bools := make([]bool, len(items))
+		for i, it := range items {
+			bv, ok := it.(bool)
+			if !ok {
+				return 0, fmt.Errorf("element %d: expected bool, got %T", i, it)
+			}
+			bools[i] = bv
+		}
+		b.StartVector(flatbuffers.SizeBool, len(bools), flatbuffers.SizeBool)
+		for i := len(bools) - 1; i >= 0; i-- {
+			b.PrependBool(bools[i])
+		}
+		return b.EndVector(len(bools)), nil
+	case FieldInt8, FieldInt16, FieldInt32, FieldInt64, FieldUint8, FieldUint16, FieldUint32, FieldUint64:
+		w := f.ElemType.width()
+		signed := f.ElemType == FieldInt8 || f.ElemType == FieldInt16 || f.ElemType == FieldInt32 || f.ElemType == FieldInt64
+		b.StartVector(w.Size(), len(items), w.Size())
+		for i := len(items) - 1; i >= 0; i-- {
+			n, err := jsonNumberToInt64(items[i])
+			if err != nil {
+				return 0, fmt.Errorf("element %d: %w", i, err)
+			}
+			if signed {
+				fbwire.PrependVectorInt(b, n, w)
+			} else {
+				fbwire.PrependVectorUint(b, uint64(n), w)
+			}
+		}
+		return b.EndVector(len(items)), nil
+	case FieldFloat32, FieldFloat64:
+		w := f.ElemType.width()
+		b.StartVector(w.Size(), len(items), w.Size())
+		for i := len(items) - 1; i >= 0; i-- {
+			f64, ok := items[i].(float64)
+			if !ok {
+				return 0, fmt.Errorf("element %d: expected number, got %T", i, items[i])
+			}
+			fbwire.PrependVectorFloat(b, f64, w)
+		}
+		return b.EndVector(len(items)), nil
+	case FieldString:
+		offs := make([]flatbuffers.UOffsetT, len(items))
+		for i, it := range items {
+			s, ok := it.(string)
+			if !ok {
+				return 0, fmt.Errorf("element %d: expected string, got %T", i, it)
+			}
+			offs[i] = b.CreateString(s)
+		}
+		b.StartVector(flatbuffers.SizeUOffsetT, len(offs), flatbuffers.SizeUOffsetT)
+		for i := len(offs) - 1; i >= 0; i-- {
+			b.PrependUOffsetT(offs[i])
+		}
+		return b.EndVector(len(offs)), nil
+	case FieldTable:
+		if f.Elem == nil {
+			return 0, fmt.Errorf("vector-of-table field missing nested schema")
+		}
+		offs := make([]flatbuffers.UOffsetT, len(items))
+		for i, it := range items {
+			nested, ok := it.(map[string]interface{})
+			if !ok {
+				return 0, fmt.Errorf("element %d: expected object, got %T", i, it)
+			}
+			off, err := mapToTable(b, nested, f.Elem)
+			if err != nil {
+				return 0, fmt.Errorf("element %d: %w", i, err)
+			}
+			offs[i] = off
+		}
+		b.StartVector(flatbuffers.SizeUOffsetT, len(offs), flatbuffers.SizeUOffsetT)
+		for i := len(offs) - 1; i >= 0; i-- {
+			b.PrependUOffsetT(offs[i])
+		}
+		return b.EndVector(len(offs)), nil
+	default:
+		return 0, fmt.Errorf("unsupported vector element type %v", f.ElemType)
+	}
+}
+
+func jsonNumberToInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case float64:
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	default:
+		return 0, fmt.Errorf("expected number, got %T", v)
+	}
+}
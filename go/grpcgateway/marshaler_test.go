@@ -0,0 +1,157 @@
+package grpcgateway
+
+import (
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// buildUint32Table serializes a table the way flatc-generated code would
+// for a single `buttons:uint32` field at vtable slot 0 -- the scenario the
+// fixed-width GetUint64/PrependUint64Slot bug silently misread.
+func buildUint32Table(t *testing.T, buttons uint32) []byte {
+	t.Helper()
+	b := flatbuffers.NewBuilder(0)
+	b.StartObject(1)
+	b.PrependUint32Slot(0, buttons, 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+func TestTableToMapReadsNarrowWidthFields(t *testing.T) {
+	schema := &TableSchema{
+		Name: "Joystick",
+		Fields: []FieldSchema{
+			{Name: "buttons", Type: FieldUint32, Offset: 4},
+		},
+	}
+	obj, err := tableToMap(buildUint32Table(t, 7), schema)
+	if err != nil {
+		t.Fatalf("tableToMap: %v", err)
+	}
+	if got, want := obj["buttons"], uint64(7); got != want {
+		t.Errorf("buttons = %v, want %v", got, want)
+	}
+}
+
+func TestMapToTableWritesNarrowWidthFields(t *testing.T) {
+	schema := &TableSchema{
+		Name: "Joystick",
+		Fields: []FieldSchema{
+			{Name: "buttons", Type: FieldUint32, Offset: 4},
+		},
+	}
+	b := flatbuffers.NewBuilder(0)
+	offset, err := mapToTable(b, map[string]interface{}{"buttons": float64(7)}, schema)
+	if err != nil {
+		t.Fatalf("mapToTable: %v", err)
+	}
+	b.Finish(offset)
+	raw := b.FinishedBytes()
+
+	table := &flatbuffers.Table{Bytes: raw, Pos: flatbuffers.GetUOffsetT(raw)}
+	off := table.Offset(4)
+	if off == 0 {
+		t.Fatal("buttons field not present in encoded table")
+	}
+	if got, want := table.GetUint32(table.Pos+flatbuffers.UOffsetT(off)), uint32(7); got != want {
+		t.Errorf("encoded buttons = %v, want %v", got, want)
+	}
+}
+
+func TestTableToMapRecoversFromMalformedPayload(t *testing.T) {
+	schema := &TableSchema{
+		Name: "Joystick",
+		Fields: []FieldSchema{
+			{Name: "buttons", Type: FieldUint32, Offset: 4},
+		},
+	}
+	for _, raw := range [][]byte{{0x01}, {0xff, 0xff, 0xff, 0xff}} {
+		if _, err := tableToMap(raw, schema); err == nil {
+			t.Errorf("tableToMap(%v) = nil error, want one reporting the malformed table", raw)
+		}
+	}
+}
+
+func TestMapToTableRejectsMissingNestedSchema(t *testing.T) {
+	schema := &TableSchema{
+		Name: "Nested",
+		Fields: []FieldSchema{
+			{Name: "child", Type: FieldTable, Offset: 4}, // Elem left nil on purpose
+		},
+	}
+	b := flatbuffers.NewBuilder(0)
+	if _, err := mapToTable(b, map[string]interface{}{"child": map[string]interface{}{}}, schema); err == nil {
+		t.Error("mapToTable with missing nested schema = nil error, want one")
+	}
+}
+
+func TestVectorOfScalarsRoundTrips(t *testing.T) {
+	schema := &TableSchema{
+		Name: "Samples",
+		Fields: []FieldSchema{
+			{Name: "readings", Type: FieldVector, ElemType: FieldFloat32, Offset: 4},
+		},
+	}
+	b := flatbuffers.NewBuilder(0)
+	offset, err := mapToTable(b, map[string]interface{}{"readings": []interface{}{1.5, -2.5, 3.0}}, schema)
+	if err != nil {
+		t.Fatalf("mapToTable: %v", err)
+	}
+	b.Finish(offset)
+
+	obj, err := tableToMap(b.FinishedBytes(), schema)
+	if err != nil {
+		t.Fatalf("tableToMap: %v", err)
+	}
+	got, ok := obj["readings"].([]float64)
+	if !ok {
+		t.Fatalf("readings = %T, want []float64", obj["readings"])
+	}
+	want := []float64{1.5, -2.5, 3}
+	if len(got) != len(want) {
+		t.Fatalf("readings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("readings[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestVectorOfTablesRoundTrips(t *testing.T) {
+	pointSchema := &TableSchema{
+		Name: "Point",
+		Fields: []FieldSchema{
+			{Name: "x", Type: FieldFloat32, Offset: 4},
+		},
+	}
+	schema := &TableSchema{
+		Name: "Path",
+		Fields: []FieldSchema{
+			{Name: "points", Type: FieldVector, ElemType: FieldTable, Elem: pointSchema, Offset: 4},
+		},
+	}
+	points := []interface{}{
+		map[string]interface{}{"x": float64(1)},
+		map[string]interface{}{"x": float64(2)},
+	}
+	b := flatbuffers.NewBuilder(0)
+	offset, err := mapToTable(b, map[string]interface{}{"points": points}, schema)
+	if err != nil {
+		t.Fatalf("mapToTable: %v", err)
+	}
+	b.Finish(offset)
+
+	obj, err := tableToMap(b.FinishedBytes(), schema)
+	if err != nil {
+		t.Fatalf("tableToMap: %v", err)
+	}
+	got, ok := obj["points"].([]map[string]interface{})
+	if !ok || len(got) != 2 {
+		t.Fatalf("points = %#v, want 2 nested objects", obj["points"])
+	}
+	if got[0]["x"] != float64(1) || got[1]["x"] != float64(2) {
+		t.Errorf("points = %#v, want x values 1 and 2", got)
+	}
+}
@@ -0,0 +1,126 @@
+package grpcgateway
+
+import (
+	"fmt"
+
+	"github.com/RealtimeRoboticsGroup/aos/go/internal/fbwire"
+)
+
+// FieldType enumerates the FlatBuffer scalar and reference kinds the JSON
+// marshaler knows how to convert. It intentionally covers only the subset of
+// reflection.BaseType values that show up in AOS channel schemas; vectors of
+// scalars and nested tables are the common case on real robots. Integer and
+// float kinds are split by width (e.g. FieldUint32 vs FieldUint64) because
+// flatc always generates the narrowest accessor that fits a field's
+// declared type, and reading a narrower field with a wider accessor reads
+// past it into whatever follows on the wire.
+type FieldType int
+
+const (
+	FieldBool FieldType = iota
+	FieldInt8
+	FieldInt16
+	FieldInt32
+	FieldInt64
+	FieldUint8
+	FieldUint16
+	FieldUint32
+	FieldUint64
+	FieldFloat32
+	FieldFloat64
+	FieldString
+	FieldTable
+	FieldVector
+)
+
+// width reports the wire width backing a scalar FieldType, for dispatching
+// to the matching fbwire accessor. Types without a width of their own
+// (FieldBool, FieldString, FieldTable, FieldVector) get Width64; callers
+// don't use it for them.
+func (t FieldType) width() fbwire.Width {
+	switch t {
+	case FieldInt8, FieldUint8:
+		return fbwire.Width8
+	case FieldInt16, FieldUint16:
+		return fbwire.Width16
+	case FieldInt32, FieldUint32, FieldFloat32:
+		return fbwire.Width32
+	default:
+		return fbwire.Width64
+	}
+}
+
+// FieldSchema describes a single field of a FlatBuffer table: its wire
+// offset (the "vtable" slot used by flatbuffers-go's Table.Offset), its
+// type, and, for FieldTable/FieldVector, the nested element schema.
+type FieldSchema struct {
+	Name   string
+	Type   FieldType
+	Offset Voffset
+	Elem   *TableSchema // set when Type is FieldTable, or FieldVector of tables
+	// ElemType is the element type of a FieldVector field (any scalar
+	// FieldType, or FieldTable, in which case Elem gives the nested schema).
+	// Unused for every other Type.
+	ElemType FieldType
+}
+
+// Voffset mirrors flatbuffers.VOffsetT without importing the flatbuffers
+// package into this file, keeping schema definitions independent of the
+// wire-reading code in marshaler.go.
+type Voffset = uint16
+
+// TableSchema is a minimal stand-in for a FlatBuffer reflection.Object: the
+// ordered fields of one table, keyed by name for JSON marshaling and by
+// vtable offset for wire access. In a full build this would normally be
+// derived from a channel's .bfbs reflection schema; SchemaRegistry lets
+// callers register schemas however they obtain them (compiled in, parsed
+// from .bfbs, fetched from a schema service, etc).
+type TableSchema struct {
+	Name   string
+	Fields []FieldSchema
+}
+
+// FieldByName returns the field with the given name, or false if the schema
+// has no such field.
+func (t *TableSchema) FieldByName(name string) (FieldSchema, bool) {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return FieldSchema{}, false
+}
+
+// SchemaRegistry maps AOS channel names to the TableSchema of the message
+// type sent on that channel. A Server consults it once per request to know
+// how to translate between JSON and FlatBuffer bytes for a given channel.
+type SchemaRegistry struct {
+	byChannel map[string]*TableSchema
+}
+
+// NewSchemaRegistry returns an empty registry. Use Register to populate it.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{byChannel: make(map[string]*TableSchema)}
+}
+
+// Register associates schema with channel, overwriting any previous
+// registration for that channel name.
+func (r *SchemaRegistry) Register(channel string, schema *TableSchema) {
+	r.byChannel[channel] = schema
+}
+
+// Lookup returns the schema registered for channel, if any.
+func (r *SchemaRegistry) Lookup(channel string) (*TableSchema, bool) {
+	s, ok := r.byChannel[channel]
+	return s, ok
+}
+
+// MustLookup is Lookup but panics on a missing channel; it is meant for use
+// during setup (alongside Register), not while serving requests.
+func (r *SchemaRegistry) MustLookup(channel string) *TableSchema {
+	s, ok := r.Lookup(channel)
+	if !ok {
+		panic(fmt.Sprintf("grpcgateway: no schema registered for channel %q", channel))
+	}
+	return s
+}
@@ -0,0 +1,162 @@
+package grpcgateway
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// channelsPrefix is the path prefix every channel route is mounted under.
+// It intentionally has no trailing slash: AOS channel names already start
+// with one (e.g. "/joystick"), so a request path is simply channelsPrefix
+// followed by the channel name verbatim ("/channels" + "/joystick" =
+// "/channels/joystick"). Requiring callers to percent-encode the channel's
+// own slash instead (so it reads as one opaque "/channels/{name}" segment)
+// decodes back into a doubled slash, which net/http's ServeMux treats as a
+// non-canonical path and 301-redirects away before Server ever sees the
+// request -- exactly the channels this bridge exists for.
+const channelsPrefix = "/channels"
+
+// Server mounts a REST/SSE surface over AOS channels, translating
+// FlatBuffer payloads to and from JSON via the schemas registered in a
+// SchemaRegistry (see package doc for the endpoint shapes). It implements
+// http.Handler, so it can be mounted directly with http.Handle or wrapped
+// by another mux/middleware.
+type Server struct {
+	backend ChannelBackend
+	schemas *SchemaRegistry
+}
+
+// NewServer returns a Server that serves the channels registered in
+// schemas, reading and writing them over conn.
+func NewServer(conn *grpc.ClientConn, schemas *SchemaRegistry) *Server {
+	return NewServerWithBackend(&grpcChannelBackend{conn: conn}, schemas)
+}
+
+// NewServerWithBackend is like NewServer but takes a ChannelBackend
+// directly, for talking to a channel source other than a live ClientConn
+// (used by tests to substitute a fake).
+func NewServerWithBackend(backend ChannelBackend, schemas *SchemaRegistry) *Server {
+	return &Server{backend: backend, schemas: schemas}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	channel, stream, ok := parseChannelPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	schema, ok := s.schemas.Lookup(channel)
+	if !ok {
+		http.Error(w, fmt.Sprintf("grpcgateway: unknown channel %q", channel), http.StatusNotFound)
+		return
+	}
+	marshaler := NewFlatbufferMarshaler(schema)
+
+	switch {
+	case r.Method == http.MethodGet && stream:
+		s.serveStream(w, r, channel, marshaler)
+	case r.Method == http.MethodGet:
+		s.serveFetch(w, r, channel, marshaler)
+	case r.Method == http.MethodPost:
+		s.serveSend(w, r, channel, marshaler)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// parseChannelPath splits a request path of the form
+// "/channels{channel}" or "/channels{channel}/stream" into the channel
+// name (including its own leading slash) and whether the streaming
+// suffix was present. ok is false for anything outside channelsPrefix or
+// with an empty channel name.
+func parseChannelPath(path string) (channel string, stream bool, ok bool) {
+	if !strings.HasPrefix(path, channelsPrefix+"/") {
+		return "", false, false
+	}
+	rest := strings.TrimPrefix(path, channelsPrefix)
+	if trimmed := strings.TrimSuffix(rest, "/stream"); trimmed != rest {
+		rest = trimmed
+		stream = true
+	}
+	if rest == "" || rest == "/" {
+		return "", false, false
+	}
+	return rest, stream, true
+}
+
+func (s *Server) serveFetch(w http.ResponseWriter, r *http.Request, channel string, m *FlatbufferMarshaler) {
+	raw, err := s.backend.Fetch(r.Context(), channel)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	body, err := m.Marshal(raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", m.ContentType(nil))
+	w.Write(body)
+}
+
+func (s *Server) serveSend(w http.ResponseWriter, r *http.Request, channel string, m *FlatbufferMarshaler) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var raw []byte
+	if err := m.Unmarshal(data, &raw); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.backend.Send(r.Context(), channel, raw); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// serveStream streams successive fetcher messages on channel as
+// Server-Sent-Events. Browsers consume SSE natively via EventSource; it is
+// used here in place of a WebSocket upgrade to avoid pulling in an
+// additional dependency for one-directional streaming.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, channel string, m *FlatbufferMarshaler) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	msgs, err := s.backend.Stream(r.Context(), channel)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case raw, ok := <-msgs:
+			if !ok {
+				return
+			}
+			body, err := m.Marshal(raw)
+			if err != nil {
+				continue // drop a malformed message rather than closing the stream
+			}
+			fmt.Fprintf(w, "data: %s\n\n", body)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package grpcgateway
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeChannelService is a minimal in-process stand-in for AOS's channel
+// gRPC service, serving Fetch/Send/Stream for any number of channels over
+// the same raw-bytes wire convention as grpcChannelBackend. It is
+// registered with grpc.UnknownServiceHandler so it doesn't need generated
+// stubs, mirroring how grpcChannelBackend calls it.
+type fakeChannelService struct {
+	mu       sync.Mutex
+	values   map[string][]byte
+	watchers map[string][]chan []byte
+}
+
+func newFakeChannelService() *fakeChannelService {
+	return &fakeChannelService{
+		values:   make(map[string][]byte),
+		watchers: make(map[string][]chan []byte),
+	}
+}
+
+func (f *fakeChannelService) handle(_ interface{}, stream grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "no method in stream")
+	}
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	channels := md.Get(channelMetadataKey)
+	if len(channels) == 0 {
+		return status.Error(codes.InvalidArgument, "missing "+channelMetadataKey+" metadata")
+	}
+	channel := channels[0]
+
+	switch method {
+	case methodFetch:
+		var req []byte
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		f.mu.Lock()
+		reply := f.values[channel]
+		f.mu.Unlock()
+		return stream.SendMsg(&reply)
+
+	case methodSend:
+		var req []byte
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		f.mu.Lock()
+		f.values[channel] = append([]byte(nil), req...)
+		for _, ch := range f.watchers[channel] {
+			ch <- append([]byte(nil), req...)
+		}
+		f.mu.Unlock()
+		var reply []byte
+		return stream.SendMsg(&reply)
+
+	case methodStream:
+		var req []byte
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		ch := make(chan []byte, 8)
+		f.mu.Lock()
+		f.watchers[channel] = append(f.watchers[channel], ch)
+		f.mu.Unlock()
+		for {
+			select {
+			case msg := <-ch:
+				if err := stream.SendMsg(&msg); err != nil {
+					return err
+				}
+			case <-stream.Context().Done():
+				return nil
+			}
+		}
+
+	default:
+		return status.Errorf(codes.Unimplemented, "unknown method %q", method)
+	}
+}
+
+// dialFakeServer starts svc on a loopback listener and returns a
+// ClientConn dialed to it, along with a cleanup func.
+func dialFakeServer(t *testing.T, svc *fakeChannelService) (*grpc.ClientConn, func()) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := grpc.NewServer(grpc.UnknownServiceHandler(svc.handle), grpc.ForceServerCodec(bytesCodec{}))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	// nolint:staticcheck // grpc.WithInsecure/WithBlock match the grpc
+	// version this module is pinned to.
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return conn, func() { conn.Close() }
+}
+
+var joystickSchema = &TableSchema{
+	Name: "Joystick",
+	Fields: []FieldSchema{
+		{Name: "buttons", Type: FieldUint32, Offset: 4},
+	},
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, func()) {
+	t.Helper()
+	svc := newFakeChannelService()
+	conn, closeConn := dialFakeServer(t, svc)
+
+	schemas := NewSchemaRegistry()
+	schemas.Register("/joystick", joystickSchema)
+
+	gw := NewServer(conn, schemas)
+	hs := httptest.NewServer(gw)
+	return hs, func() {
+		hs.Close()
+		closeConn()
+	}
+}
+
+func TestServerFetchReturnsSentMessage(t *testing.T) {
+	hs, cleanup := newTestServer(t)
+	defer cleanup()
+
+	resp, err := http.Post(hs.URL+"/channels/joystick", "application/json", strings.NewReader(`{"buttons":7}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("post status = %d, body = %s", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(hs.URL + "/channels/joystick")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(body)), `{"buttons":7}`; got != want {
+		t.Errorf("GET /channels/joystick = %q, want %q", got, want)
+	}
+}
+
+// TestServerWorksBehindServeMux mounts Server the way doc.go documents
+// ("mounted directly with http.Handle"), which runs requests through
+// net/http's path cleaning. A channel path built by naively concatenating
+// channelsPrefix with a channel name of the percent-encoded-slash form
+// this test used to use would decode to a double slash and get
+// 301-redirected away before Server ever saw it; channel names carrying
+// their own leading slash must not trigger that.
+func TestServerWorksBehindServeMux(t *testing.T) {
+	svc := newFakeChannelService()
+	conn, closeConn := dialFakeServer(t, svc)
+	defer closeConn()
+
+	schemas := NewSchemaRegistry()
+	schemas.Register("/joystick", joystickSchema)
+	gw := NewServer(conn, schemas)
+
+	mux := http.NewServeMux()
+	mux.Handle("/channels/", gw)
+	hs := httptest.NewServer(mux)
+	defer hs.Close()
+
+	resp, err := http.Post(hs.URL+"/channels/joystick", "application/json", strings.NewReader(`{"buttons":9}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("post status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+
+	resp, err = http.Get(hs.URL + "/channels/joystick")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get status = %d, want %d (ServeMux may have redirected)", resp.StatusCode, http.StatusOK)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(body)), `{"buttons":9}`; got != want {
+		t.Errorf("GET /channels/joystick via ServeMux = %q, want %q", got, want)
+	}
+}
+
+func TestServerUnknownChannelReturns404(t *testing.T) {
+	hs, cleanup := newTestServer(t)
+	defer cleanup()
+
+	resp, err := http.Get(hs.URL + "/channels/no-such-channel")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestServerStreamDeliversSentMessages(t *testing.T) {
+	hs, cleanup := newTestServer(t)
+	defer cleanup()
+
+	req, err := http.NewRequest(http.MethodGet, hs.URL+"/channels/joystick/stream", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("stream status = %d", resp.StatusCode)
+	}
+
+	done := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		n, _ := resp.Body.Read(buf)
+		done <- string(buf[:n])
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let the stream subscribe before publishing
+	sendResp, err := http.Post(hs.URL+"/channels/joystick", "application/json", strings.NewReader(`{"buttons":3}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	sendResp.Body.Close()
+
+	select {
+	case event := <-done:
+		if !strings.Contains(event, `{"buttons":3}`) {
+			t.Errorf("stream event = %q, want it to contain buttons:3", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for SSE event")
+	}
+}
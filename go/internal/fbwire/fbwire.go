@@ -0,0 +1,155 @@
+// Package fbwire holds the width-specific FlatBuffer scalar accessors
+// shared by grpcgateway and fbvalidate, which both need to read and write
+// table fields according to the field's actual wire width rather than
+// always going through the 64-bit accessors. flatc-generated tables use
+// the narrowest accessor that fits a field's declared type (a
+// `buttons:uint32` field is read with GetUint32, not GetUint64), so code
+// that always reaches for the 64-bit family misreads every narrower field.
+package fbwire
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Width identifies the wire width of a scalar FlatBuffer field, matching
+// one of flatbuffers-go's width-specific Get*/Prepend*Slot accessor
+// families.
+type Width int
+
+const (
+	Width8 Width = iota
+	Width16
+	Width32
+	Width64
+)
+
+// Size returns the number of bytes a scalar of width w occupies on the
+// wire, for computing vector element strides.
+func (w Width) Size() int {
+	switch w {
+	case Width8:
+		return 1
+	case Width16:
+		return 2
+	case Width32:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// ReadInt reads a signed integer of width w at pos in bytes.
+func ReadInt(bytes []byte, pos flatbuffers.UOffsetT, w Width) int64 {
+	switch w {
+	case Width8:
+		return int64(flatbuffers.GetInt8(bytes[pos:]))
+	case Width16:
+		return int64(flatbuffers.GetInt16(bytes[pos:]))
+	case Width32:
+		return int64(flatbuffers.GetInt32(bytes[pos:]))
+	default:
+		return flatbuffers.GetInt64(bytes[pos:])
+	}
+}
+
+// ReadUint reads an unsigned integer of width w at pos in bytes.
+func ReadUint(bytes []byte, pos flatbuffers.UOffsetT, w Width) uint64 {
+	switch w {
+	case Width8:
+		return uint64(flatbuffers.GetUint8(bytes[pos:]))
+	case Width16:
+		return uint64(flatbuffers.GetUint16(bytes[pos:]))
+	case Width32:
+		return uint64(flatbuffers.GetUint32(bytes[pos:]))
+	default:
+		return flatbuffers.GetUint64(bytes[pos:])
+	}
+}
+
+// ReadFloat reads a floating-point number of width w (Width32 or Width64;
+// any narrower width is treated as Width32) at pos in bytes.
+func ReadFloat(bytes []byte, pos flatbuffers.UOffsetT, w Width) float64 {
+	if w == Width64 {
+		return flatbuffers.GetFloat64(bytes[pos:])
+	}
+	return float64(flatbuffers.GetFloat32(bytes[pos:]))
+}
+
+// PrependInt appends a signed integer of width w to b's current object at
+// vtable slot i, the encode-side counterpart of ReadInt.
+func PrependInt(b *flatbuffers.Builder, i int, v int64, w Width) {
+	switch w {
+	case Width8:
+		b.PrependInt8Slot(i, int8(v), 0)
+	case Width16:
+		b.PrependInt16Slot(i, int16(v), 0)
+	case Width32:
+		b.PrependInt32Slot(i, int32(v), 0)
+	default:
+		b.PrependInt64Slot(i, v, 0)
+	}
+}
+
+// PrependUint appends an unsigned integer of width w to b's current object
+// at vtable slot i, the encode-side counterpart of ReadUint.
+func PrependUint(b *flatbuffers.Builder, i int, v uint64, w Width) {
+	switch w {
+	case Width8:
+		b.PrependUint8Slot(i, uint8(v), 0)
+	case Width16:
+		b.PrependUint16Slot(i, uint16(v), 0)
+	case Width32:
+		b.PrependUint32Slot(i, uint32(v), 0)
+	default:
+		b.PrependUint64Slot(i, v, 0)
+	}
+}
+
+// PrependFloat appends a floating-point number of width w (Width32 or
+// Width64; any narrower width is treated as Width32) to b's current object
+// at vtable slot i, the encode-side counterpart of ReadFloat.
+func PrependFloat(b *flatbuffers.Builder, i int, v float64, w Width) {
+	if w == Width64 {
+		b.PrependFloat64Slot(i, v, 0)
+		return
+	}
+	b.PrependFloat32Slot(i, float32(v), 0)
+}
+
+// PrependVectorInt appends a signed integer of width w as the next (unslotted)
+// element of a vector under construction, for building vectors of scalars.
+func PrependVectorInt(b *flatbuffers.Builder, v int64, w Width) {
+	switch w {
+	case Width8:
+		b.PrependInt8(int8(v))
+	case Width16:
+		b.PrependInt16(int16(v))
+	case Width32:
+		b.PrependInt32(int32(v))
+	default:
+		b.PrependInt64(v)
+	}
+}
+
+// PrependVectorUint is PrependVectorInt for unsigned integers.
+func PrependVectorUint(b *flatbuffers.Builder, v uint64, w Width) {
+	switch w {
+	case Width8:
+		b.PrependUint8(uint8(v))
+	case Width16:
+		b.PrependUint16(uint16(v))
+	case Width32:
+		b.PrependUint32(uint32(v))
+	default:
+		b.PrependUint64(v)
+	}
+}
+
+// PrependVectorFloat is PrependVectorInt for floats.
+func PrependVectorFloat(b *flatbuffers.Builder, v float64, w Width) {
+	if w == Width64 {
+		b.PrependFloat64(v)
+		return
+	}
+	b.PrependFloat32(float32(v))
+}
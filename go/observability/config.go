@@ -0,0 +1,51 @@
+package observability
+
+import "context"
+
+// AOSLogger is implemented by whatever already writes spans into AOS's
+// on-robot logging sidechannel. WithAOSLogger wires one into the tracing
+// interceptors so distributed traces can be joined with AOS log playback
+// by span ID after the fact.
+type AOSLogger interface {
+	// LogSpan records that spanID was the active span for an RPC to
+	// method. Interceptors call it once per RPC, only for RPCs matching
+	// the AOS log-stream method (see logStreamMethod): after the call
+	// completes for unary RPCs and server streams, and as soon as the
+	// stream is established for client streams.
+	LogSpan(ctx context.Context, spanID string, method string)
+}
+
+// config holds the options shared by the tracing interceptor
+// constructors.
+type config struct {
+	logger AOSLogger
+}
+
+// Option configures the tracing interceptors returned by
+// UnaryClientInterceptor, StreamClientInterceptor, UnaryServerInterceptor,
+// and StreamServerInterceptor.
+type Option func(*config)
+
+// WithAOSLogger correlates span IDs into AOS's logging system: whenever an
+// instrumented interceptor sees an RPC to AOS's log-stream service, it
+// reports the span ID for that call to logger.
+func WithAOSLogger(logger AOSLogger) Option {
+	return func(c *config) { c.logger = logger }
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// logSpanIfLogStream reports spanID to c.logger when method is AOS's
+// log-stream RPC and a logger was configured; it is a no-op otherwise.
+func (c *config) logSpanIfLogStream(ctx context.Context, spanID, method string) {
+	if c.logger == nil || method != logStreamMethod {
+		return
+	}
+	c.logger.LogSpan(ctx, spanID, method)
+}
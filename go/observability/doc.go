@@ -0,0 +1,20 @@
+// Package observability provides gRPC interceptors that instrument AOS
+// gRPC traffic with OpenTelemetry traces and Prometheus metrics.
+//
+// Tracing (tracing.go) propagates span context through gRPC metadata the
+// same way AOS carries its own per-call metadata, and annotates spans with
+// a well-known set of AOS attributes -- channel name, node name, and the
+// event loop's monotonic/realtime timestamps -- whenever callers have set
+// them (see the Metadata* keys). WithAOSLogger correlates span IDs into
+// AOS's own on-robot logging so a trace can later be joined with log
+// playback.
+//
+// Metrics (metrics.go) exports request counts, latency histograms,
+// in-flight RPC gauges, and per-channel message-rate/drop counters to a
+// Prometheus registry.
+//
+// Both instrument via the standard grpc.UnaryClientInterceptor,
+// grpc.StreamClientInterceptor, grpc.UnaryServerInterceptor, and
+// grpc.StreamServerInterceptor types, so they compose with any other
+// interceptor using grpc.ChainUnaryInterceptor / grpc.ChainStreamInterceptor.
+package observability
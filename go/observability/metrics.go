@@ -0,0 +1,231 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ClientMetrics exports Prometheus metrics for outgoing AOS gRPC traffic:
+// request counts and latency by method/code, in-flight RPCs, and
+// per-channel message-rate/drop counters keyed off MetadataChannel.
+// Construct one with NewClientMetrics and use its interceptor methods.
+type ClientMetrics struct {
+	requests     *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	channelRate  *prometheus.CounterVec
+	channelDrops *prometheus.CounterVec
+}
+
+// NewClientMetrics registers a ClientMetrics' collectors with reg and
+// returns it.
+func NewClientMetrics(reg prometheus.Registerer) *ClientMetrics {
+	m := &ClientMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "aos_grpc_client_requests_total",
+			Help: "Total number of AOS gRPC client requests, by method and status code.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "aos_grpc_client_request_duration_seconds",
+			Help:    "AOS gRPC client request latency, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "aos_grpc_client_requests_in_flight",
+			Help: "AOS gRPC client requests currently in flight, by method.",
+		}, []string{"method"}),
+		channelRate: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "aos_grpc_client_channel_messages_total",
+			Help: "Total number of AOS channel messages sent/received over gRPC, by channel.",
+		}, []string{"channel"}),
+		channelDrops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "aos_grpc_client_channel_drops_total",
+			Help: "Total number of AOS channel messages that failed to send/receive over gRPC, by channel.",
+		}, []string{"channel"}),
+	}
+	reg.MustRegister(m.requests, m.latency, m.inFlight, m.channelRate, m.channelDrops)
+	return m
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that
+// records m's metrics around each unary RPC.
+func (m *ClientMetrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		m.inFlight.WithLabelValues(method).Inc()
+		defer m.inFlight.WithLabelValues(method).Dec()
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(ctx, method, time.Since(start), err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that
+// records m's metrics for the lifetime of a streaming RPC.
+func (m *ClientMetrics) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		m.inFlight.WithLabelValues(method).Inc()
+		start := time.Now()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			m.inFlight.WithLabelValues(method).Dec()
+			m.observe(ctx, method, time.Since(start), err)
+			return nil, err
+		}
+		return &countedClientStream{ClientStream: cs, metrics: m, ctx: ctx, method: method, start: start}, nil
+	}
+}
+
+type countedClientStream struct {
+	grpc.ClientStream
+	metrics *ClientMetrics
+	ctx     context.Context
+	method  string
+	start   time.Time
+	closed  bool
+}
+
+func (s *countedClientStream) RecvMsg(reply interface{}) error {
+	err := s.ClientStream.RecvMsg(reply)
+	if err != nil {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *countedClientStream) finish(err error) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.metrics.inFlight.WithLabelValues(s.method).Dec()
+	s.metrics.observe(s.ctx, s.method, time.Since(s.start), err)
+}
+
+// observe records a completed RPC's request/latency/channel counters. err
+// may be nil (success), io.EOF (a stream ending cleanly, treated as
+// success), or any other gRPC status error.
+func (m *ClientMetrics) observe(ctx context.Context, method string, elapsed time.Duration, err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.latency.WithLabelValues(method).Observe(elapsed.Seconds())
+
+	channel := channelFromContext(ctx)
+	if channel == "" {
+		return
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		m.channelDrops.WithLabelValues(channel).Inc()
+		return
+	}
+	m.channelRate.WithLabelValues(channel).Inc()
+}
+
+func channelFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if vals := md.Get(MetadataChannel); len(vals) > 0 {
+			return vals[0]
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(MetadataChannel); len(vals) > 0 {
+			return vals[0]
+		}
+	}
+	return ""
+}
+
+// ServerMetrics is ClientMetrics' server-side counterpart: the same
+// request/latency/in-flight/channel metrics, exported under server metric
+// names so the two can share a Prometheus registry without colliding.
+type ServerMetrics struct {
+	requests     *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	channelRate  *prometheus.CounterVec
+	channelDrops *prometheus.CounterVec
+}
+
+// NewServerMetrics registers a ServerMetrics' collectors with reg and
+// returns it.
+func NewServerMetrics(reg prometheus.Registerer) *ServerMetrics {
+	m := &ServerMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "aos_grpc_server_requests_total",
+			Help: "Total number of AOS gRPC server requests handled, by method and status code.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "aos_grpc_server_request_duration_seconds",
+			Help:    "AOS gRPC server request latency, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "aos_grpc_server_requests_in_flight",
+			Help: "AOS gRPC server requests currently in flight, by method.",
+		}, []string{"method"}),
+		channelRate: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "aos_grpc_server_channel_messages_total",
+			Help: "Total number of AOS channel messages sent/received over gRPC, by channel.",
+		}, []string{"channel"}),
+		channelDrops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "aos_grpc_server_channel_drops_total",
+			Help: "Total number of AOS channel messages that failed to send/receive over gRPC, by channel.",
+		}, []string{"channel"}),
+	}
+	reg.MustRegister(m.requests, m.latency, m.inFlight, m.channelRate, m.channelDrops)
+	return m
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// records m's metrics around each unary RPC.
+func (m *ServerMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		m.inFlight.WithLabelValues(info.FullMethod).Inc()
+		defer m.inFlight.WithLabelValues(info.FullMethod).Dec()
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(ctx, info.FullMethod, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// records m's metrics for the lifetime of a streaming RPC.
+func (m *ServerMetrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		m.inFlight.WithLabelValues(info.FullMethod).Inc()
+		defer m.inFlight.WithLabelValues(info.FullMethod).Dec()
+
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(ss.Context(), info.FullMethod, time.Since(start), err)
+		return err
+	}
+}
+
+func (m *ServerMetrics) observe(ctx context.Context, method string, elapsed time.Duration, err error) {
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.latency.WithLabelValues(method).Observe(elapsed.Seconds())
+
+	channel := channelFromContext(ctx)
+	if channel == "" {
+		return
+	}
+	if err != nil {
+		m.channelDrops.WithLabelValues(channel).Inc()
+		return
+	}
+	m.channelRate.WithLabelValues(channel).Inc()
+}
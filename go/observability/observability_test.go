@@ -0,0 +1,263 @@
+package observability
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// echoMethod is a fake unary RPC used only by this test: it echoes the
+// request bytes back unchanged, so there's something for the interceptors
+// to wrap without needing a generated protobuf/flatbuffer service.
+const echoMethod = "/aos.test.Echo/Echo"
+
+// rawBytesCodec is a grpc/encoding.Codec that passes []byte payloads
+// through unmodified, the same wire convention AOS channel messages use
+// (see go/grpcgateway's bytesCodec). It lets this test's fake echo service
+// avoid generating a protobuf message just to exercise the interceptors.
+type rawBytesCodec struct{}
+
+func (rawBytesCodec) Marshal(v interface{}) ([]byte, error) {
+	b := v.(*[]byte)
+	return *b, nil
+}
+
+func (rawBytesCodec) Unmarshal(data []byte, v interface{}) error {
+	b := v.(*[]byte)
+	*b = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawBytesCodec) Name() string { return "aos-flatbuffer" }
+
+// echoServiceDesc builds a ServiceDesc exposing a single unary RPC,
+// fullMethod, that echoes its []byte request back as the response. It
+// registers a real ServiceDesc (rather than grpc.UnknownServiceHandler) so
+// that grpc-go's unary interceptor chain -- which only runs for RPCs
+// dispatched through a ServiceDesc -- actually wraps the call.
+func echoServiceDesc(serviceName, methodName, fullMethod string) grpc.ServiceDesc {
+	return grpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: methodName,
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				var req []byte
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					reply := append([]byte(nil), req.([]byte)...)
+					return &reply, nil
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+			},
+		}},
+	}
+}
+
+// startEchoServer starts an in-process gRPC server exposing echoMethod and
+// logStreamMethod (both echoing their request), instrumented with the
+// given server interceptors, and returns a dialed ClientConn plus a
+// cleanup func.
+func startEchoServer(t *testing.T, unary grpc.UnaryServerInterceptor, metricsUnary grpc.UnaryServerInterceptor) (*grpc.ClientConn, func()) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(metricsUnary, unary),
+		grpc.ForceServerCodec(rawBytesCodec{}),
+	)
+	echo := echoServiceDesc("aos.test.Echo", "Echo", echoMethod)
+	s.RegisterService(&echo, nil)
+	logSink := echoServiceDesc("aos.logging.LogSink", "Log", logStreamMethod)
+	s.RegisterService(&logSink, nil)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	// nolint:staticcheck // matches the grpc version this module is pinned to.
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return conn, func() { conn.Close() }
+}
+
+// bytesPtr returns a pointer to s's bytes, since call-site composite
+// literals like &[]byte("x") aren't addressable in Go.
+func bytesPtr(s string) *[]byte {
+	b := []byte(s)
+	return &b
+}
+
+func TestTracingInterceptorsPropagateOneTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+	prevTracer := tracer
+	tracer = tp.Tracer("test")
+	t.Cleanup(func() { tracer = prevTracer })
+
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prevPropagator) })
+
+	conn, cleanup := startEchoServer(t, UnaryServerInterceptor(), passthroughUnary)
+	defer cleanup()
+
+	uc := UnaryClientInterceptor()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataChannel, "/joystick")
+	var reply []byte
+	err := uc(ctx, echoMethod, bytesPtr("hello"), &reply, conn, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return conn.Invoke(ctx, method, req, reply, append(opts, grpc.ForceCodec(rawBytesCodec{}))...)
+	})
+	if err != nil {
+		t.Fatalf("echo call: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2 (client + server)", len(spans))
+	}
+	if spans[0].SpanContext.TraceID() != spans[1].SpanContext.TraceID() {
+		t.Errorf("client and server spans have different trace IDs: %v vs %v",
+			spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	}
+
+	foundChannelAttr := false
+	for _, span := range spans {
+		for _, attr := range span.Attributes {
+			if string(attr.Key) == MetadataChannel && attr.Value.AsString() == "/joystick" {
+				foundChannelAttr = true
+			}
+		}
+	}
+	if !foundChannelAttr {
+		t.Errorf("no span carried the %s attribute", MetadataChannel)
+	}
+}
+
+func passthroughUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(ctx, req)
+}
+
+type fakeAOSLogger struct {
+	spanIDs []string
+	methods []string
+}
+
+func (f *fakeAOSLogger) LogSpan(_ context.Context, spanID, method string) {
+	f.spanIDs = append(f.spanIDs, spanID)
+	f.methods = append(f.methods, method)
+}
+
+func TestWithAOSLoggerOnlyFiresForLogStreamMethod(t *testing.T) {
+	logger := &fakeAOSLogger{}
+	uc := UnaryClientInterceptor(WithAOSLogger(logger))
+
+	conn, cleanup := startEchoServer(t, UnaryServerInterceptor(), passthroughUnary)
+	defer cleanup()
+
+	var reply []byte
+	invoke := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return conn.Invoke(ctx, method, req, reply, append(opts, grpc.ForceCodec(rawBytesCodec{}))...)
+	}
+	if err := uc(context.Background(), echoMethod, bytesPtr("x"), &reply, conn, invoke); err != nil {
+		t.Fatalf("echo call: %v", err)
+	}
+	if len(logger.methods) != 0 {
+		t.Errorf("logger fired for %v, want no calls for a non-log-stream method", logger.methods)
+	}
+
+	if err := uc(context.Background(), logStreamMethod, bytesPtr("x"), &reply, conn, invoke); err != nil {
+		t.Fatalf("log-stream call: %v", err)
+	}
+	if len(logger.methods) != 1 || logger.methods[0] != logStreamMethod {
+		t.Errorf("logger.methods = %v, want exactly one call to %s", logger.methods, logStreamMethod)
+	}
+}
+
+func TestClientMetricsRecordRequestsAndChannelCounters(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cm := NewClientMetrics(reg)
+
+	conn, cleanup := startEchoServer(t, passthroughUnaryOTel, passthroughUnary)
+	defer cleanup()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataChannel, "/imu")
+	interceptor := cm.UnaryClientInterceptor()
+	var reply []byte
+	err := interceptor(ctx, echoMethod, bytesPtr("hi"), &reply, conn, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return conn.Invoke(ctx, method, req, reply, append(opts, grpc.ForceCodec(rawBytesCodec{}))...)
+	})
+	if err != nil {
+		t.Fatalf("echo call: %v", err)
+	}
+
+	metrics, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	requestCount := findCounter(t, metrics, "aos_grpc_client_requests_total", map[string]string{"method": echoMethod, "code": codes.OK.String()})
+	if requestCount != 1 {
+		t.Errorf("aos_grpc_client_requests_total = %v, want 1", requestCount)
+	}
+	channelCount := findCounter(t, metrics, "aos_grpc_client_channel_messages_total", map[string]string{"channel": "/imu"})
+	if channelCount != 1 {
+		t.Errorf("aos_grpc_client_channel_messages_total = %v, want 1", channelCount)
+	}
+}
+
+func passthroughUnaryOTel(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(ctx, req)
+}
+
+// findCounter returns the value of the counter metric named name whose
+// labels match wantLabels exactly, or fails the test if none is found.
+func findCounter(t *testing.T, families []*dto.MetricFamily, name string, wantLabels map[string]string) float64 {
+	t.Helper()
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labelsEqual(labels, wantLabels) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	t.Fatalf("no metric %s with labels %v found", name, wantLabels)
+	return 0
+}
+
+func labelsEqual(got, want map[string]string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for k, v := range want {
+		if got[k] != v {
+			return false
+		}
+	}
+	return true
+}
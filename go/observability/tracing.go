@@ -0,0 +1,223 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Well-known AOS gRPC metadata keys. AOS code that wants a call's spans to
+// carry this context sets these keys on the outgoing context itself; the
+// interceptors below only read and forward them, never invent values.
+const (
+	MetadataChannel          = "aos-channel"
+	MetadataNode             = "aos-node"
+	MetadataMonotonicEventNs = "aos-event-loop-monotonic-ns"
+	MetadataRealtimeEventNs  = "aos-event-loop-realtime-ns"
+)
+
+// logStreamMethod is the RPC WithAOSLogger watches for: the method AOS's
+// own log-stream service uses to ship log records off a node.
+const logStreamMethod = "/aos.logging.LogSink/Log"
+
+// tracer resolves against whatever TracerProvider the process has
+// registered with otel.SetTracerProvider; there is nothing to construct
+// here, so package init stays cheap when tracing isn't configured.
+var tracer = otel.Tracer("github.com/RealtimeRoboticsGroup/aos/go/observability")
+
+// metadataCarrier adapts grpc metadata.MD to otel's
+// propagation.TextMapCarrier, so trace context travels in the same gRPC
+// metadata AOS already uses to carry channel/node attributes.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	vals := metadata.MD(c).Get(key)
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// aosAttributes reads the Metadata* keys off ctx's outgoing or incoming
+// metadata (whichever is present) and returns them as span attributes,
+// omitting any that aren't set.
+func aosAttributes(ctx context.Context) []attribute.KeyValue {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md, ok = metadata.FromIncomingContext(ctx)
+	}
+	if !ok {
+		return nil
+	}
+	keys := []string{MetadataChannel, MetadataNode, MetadataMonotonicEventNs, MetadataRealtimeEventNs}
+	var attrs []attribute.KeyValue
+	for _, k := range keys {
+		vals := md.Get(k)
+		if len(vals) == 0 {
+			continue
+		}
+		attrs = append(attrs, attribute.String(k, vals[0]))
+	}
+	return attrs
+}
+
+// finishSpan records err on span, if any, and ends it.
+func finishSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that starts
+// a client span around each unary RPC, injects it into outgoing gRPC
+// metadata so the server side can continue the trace, and tags it with
+// whatever AOS metadata (see Metadata* constants) is already on ctx.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(aosAttributes(ctx)...))
+		defer span.End()
+
+		ctx = injectTraceContext(ctx)
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		finishSpan(span, err)
+		cfg.logSpanIfLogStream(ctx, span.SpanContext().SpanID().String(), method)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that
+// starts a client span for the lifetime of a streaming RPC and injects it
+// into outgoing gRPC metadata, mirroring UnaryClientInterceptor.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(aosAttributes(ctx)...))
+		ctx = injectTraceContext(ctx)
+
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			finishSpan(span, err)
+			return nil, err
+		}
+		cfg.logSpanIfLogStream(ctx, span.SpanContext().SpanID().String(), method)
+		return &tracedClientStream{ClientStream: cs, span: span}, nil
+	}
+}
+
+// tracedClientStream ends its span when RecvMsg returns an error (io.EOF, a
+// clean end of stream, is not recorded as an error on the span) or when
+// CloseSend fails. A successful CloseSend and errors from SendMsg leave the
+// span open.
+type tracedClientStream struct {
+	grpc.ClientStream
+	span trace.Span
+}
+
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		finishSpan(s.span, errOrNilOnEOF(err))
+	}
+	return err
+}
+
+func (s *tracedClientStream) CloseSend() error {
+	err := s.ClientStream.CloseSend()
+	if err != nil {
+		finishSpan(s.span, err)
+	}
+	return err
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that starts
+// a server span continuing whatever trace context arrived in the
+// request's incoming gRPC metadata, and tags it with AOS attributes.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = extractTraceContext(ctx)
+		ctx, span := tracer.Start(ctx, info.FullMethod, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(aosAttributes(ctx)...))
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		finishSpan(span, err)
+		cfg.logSpanIfLogStream(ctx, span.SpanContext().SpanID().String(), info.FullMethod)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// starts a server span for the lifetime of a streaming RPC, continuing
+// whatever trace context the client injected.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := extractTraceContext(ss.Context())
+		ctx, span := tracer.Start(ctx, info.FullMethod, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(aosAttributes(ctx)...))
+		defer span.End()
+
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx})
+		finishSpan(span, err)
+		cfg.logSpanIfLogStream(ctx, span.SpanContext().SpanID().String(), info.FullMethod)
+		return err
+	}
+}
+
+// tracedServerStream overrides Context so handlers observe the span-bearing
+// context StreamServerInterceptor derived from the incoming trace context.
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedServerStream) Context() context.Context { return s.ctx }
+
+func injectTraceContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func extractTraceContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+}
+
+// errOrNilOnEOF suppresses io.EOF, the expected signal that a stream ended
+// cleanly, so it isn't recorded on the span as an error.
+func errOrNilOnEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}